@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Archiver writes a copy of every processed message to a local mbox file
+// before it's labeled or trashed, so users can keep a searchable archive
+// independent of the mailbox's own retention. Gated behind ARCHIVE_DIR;
+// nil (not configured) is the common case and Archive is a no-op on it.
+type Archiver struct {
+	dir string
+	mu  sync.Mutex // serializes appends to the current month's mbox file
+}
+
+// newArchiverFromEnv returns an Archiver rooted at ARCHIVE_DIR, or nil if
+// that env var isn't set.
+func newArchiverFromEnv() (*Archiver, error) {
+	dir := os.Getenv("ARCHIVE_DIR")
+	if dir == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating ARCHIVE_DIR %q: %w", dir, err)
+	}
+	return &Archiver{dir: dir}, nil
+}
+
+// Archive appends msg to the current month's mbox file, tagged with the
+// outcome of processing it. ocrText is the concatenation of every OCR'd
+// line across msg's images, and labels reflects where the message ended
+// up (e.g. ["USPS"] or ["TRASH"]), mirroring Gmail's own X-Gmail-Labels
+// export header so the archive can be re-imported by Gmail-export-aware
+// tools.
+func (a *Archiver) Archive(msg *MailMessage, ocrText, matchedName string, labels []string) error {
+	if a == nil {
+		return nil
+	}
+
+	entry, err := a.buildEntry(msg, ocrText, matchedName, labels)
+	if err != nil {
+		return fmt.Errorf("building archive entry for %s: %w", msg.ID, err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	path := filepath.Join(a.dir, time.Now().Format("2006-01")+".mbox")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("opening mbox %q: %w", path, err)
+	}
+	defer func() {
+		if cerr := f.Close(); cerr != nil {
+			log.Printf("warning: closing mbox %q: %v", path, cerr)
+		}
+	}()
+
+	if _, err := f.Write(entry); err != nil {
+		return fmt.Errorf("writing to mbox %q: %w", path, err)
+	}
+	return nil
+}
+
+func (a *Archiver) buildEntry(msg *MailMessage, ocrText, matchedName string, labels []string) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	// mbox format requires each message to start with a "From " envelope
+	// line (the postmark line) and separates entries with a blank line.
+	// Every header value is derived from the mail itself (sender, subject)
+	// or OCR'd text, so each is newline-stripped before being written —
+	// otherwise a crafted header/scan could inject extra header lines or
+	// even a forged "From " envelope line into the mbox file.
+	fmt.Fprintf(&body, "From usps-informed-checker %s\r\n", time.Now().UTC().Format(time.ANSIC))
+	fmt.Fprintf(&body, "From: %s\r\n", mboxQuoteHeader(msg.Sender))
+	fmt.Fprintf(&body, "Subject: %s\r\n", mboxQuoteHeader(msg.Subject))
+	fmt.Fprintf(&body, "X-USPS-Matched-Name: %s\r\n", mboxQuoteHeader(matchedName))
+	fmt.Fprintf(&body, "X-USPS-OCR-Text: %s\r\n", mboxQuoteHeader(ocrText))
+	fmt.Fprintf(&body, "X-Gmail-Labels: %s\r\n", mboxQuoteHeader(strings.Join(labels, ",")))
+	fmt.Fprintf(&body, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&body, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", writer.Boundary())
+
+	textPart, err := writer.CreatePart(map[string][]string{
+		"Content-Type": {"text/plain; charset=UTF-8"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(textPart, "%s\n", mboxQuoteFromLines(ocrText))
+
+	for i, imageData := range msg.Images {
+		imgPart, err := writer.CreatePart(map[string][]string{
+			"Content-Type":              {"image/jpeg"},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {fmt.Sprintf(`attachment; filename="image-%d.jpg"`, i+1)},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64Wrapped(imgPart, imageData); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(&body, "\r\n")
+	return body.Bytes(), nil
+}
+
+// mboxQuoteFromLines applies mboxrd-style quoting to body text: any line
+// that looks like an envelope separator ("From ", or an already-quoted
+// ">From ", ">>From ", etc.) gets an extra ">" prepended. Mail-piece scans
+// routinely OCR an address block line like "From John Smith", which many
+// mbox readers would otherwise mistake for the start of the next message.
+func mboxQuoteFromLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimLeft(line, ">"), "From ") {
+			lines[i] = ">" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mboxQuoteHeader collapses newlines so a message's own sender/subject/OCR
+// text can't inject extra header lines (or a forged mbox "From " envelope
+// line) into the archive file.
+func mboxQuoteHeader(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", " ")
+	s = strings.ReplaceAll(s, "\n", " ")
+	s = strings.ReplaceAll(s, "\r", " ")
+	return s
+}
+
+// base64LineLength is RFC 2045's required wrap width for base64 MIME
+// bodies; some strict parsers reject unwrapped single-line attachments.
+const base64LineLength = 76
+
+// writeBase64Wrapped base64-encodes data and writes it to w wrapped at
+// base64LineLength per RFC 2045.
+func writeBase64Wrapped(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > base64LineLength {
+		if _, err := fmt.Fprintf(w, "%s\r\n", encoded[:base64LineLength]); err != nil {
+			return err
+		}
+		encoded = encoded[base64LineLength:]
+	}
+	_, err := fmt.Fprintf(w, "%s\r\n", encoded)
+	return err
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// azureEngine implements OCREngine via Azure AI Vision's Read API, which is
+// asynchronous: a POST kicks off the analysis and the result is retrieved
+// by polling the Operation-Location URL it returns.
+type azureEngine struct {
+	endpoint   string
+	key        string
+	httpClient *http.Client
+}
+
+func newAzureEngine() (*azureEngine, error) {
+	endpoint := os.Getenv("AZURE_VISION_ENDPOINT")
+	key := os.Getenv("AZURE_VISION_KEY")
+	if endpoint == "" || key == "" {
+		return nil, fmt.Errorf("AZURE_VISION_ENDPOINT and AZURE_VISION_KEY are required when OCR_BACKEND=azure")
+	}
+	return &azureEngine{endpoint: endpoint, key: key, httpClient: &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+func (e *azureEngine) DetectText(ctx context.Context, imageBytes []byte) (OCRResult, error) {
+	opURL, err := e.submitReadJob(ctx, imageBytes)
+	if err != nil {
+		return OCRResult{}, err
+	}
+	return e.pollReadResult(ctx, opURL)
+}
+
+func (e *azureEngine) submitReadJob(ctx context.Context, imageBytes []byte) (string, error) {
+	url := fmt.Sprintf("%s/vision/v3.2/read/analyze", e.endpoint)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(imageBytes))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Ocp-Apim-Subscription-Key", e.key)
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("azure read submit: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return "", fmt.Errorf("azure read submit: unexpected status %s", resp.Status)
+	}
+	opURL := resp.Header.Get("Operation-Location")
+	if opURL == "" {
+		return "", fmt.Errorf("azure read submit: missing Operation-Location header")
+	}
+	return opURL, nil
+}
+
+type azureReadResult struct {
+	Status        string `json:"status"`
+	AnalyzeResult struct {
+		ReadResults []struct {
+			Lines []struct {
+				Text       string  `json:"text"`
+				Confidence float32 `json:"confidence"`
+			} `json:"lines"`
+		} `json:"readResults"`
+	} `json:"analyzeResult"`
+}
+
+func (e *azureEngine) pollReadResult(ctx context.Context, opURL string) (OCRResult, error) {
+	const pollInterval = time.Second
+	const maxAttempts = 30
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, opURL, nil)
+		if err != nil {
+			return OCRResult{}, err
+		}
+		req.Header.Set("Ocp-Apim-Subscription-Key", e.key)
+
+		resp, err := e.httpClient.Do(req)
+		if err != nil {
+			return OCRResult{}, fmt.Errorf("azure read poll: %w", err)
+		}
+		var result azureReadResult
+		decodeErr := json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if decodeErr != nil {
+			return OCRResult{}, fmt.Errorf("azure read poll: %w", decodeErr)
+		}
+
+		switch result.Status {
+		case "succeeded":
+			return toOCRResult(result), nil
+		case "failed":
+			return OCRResult{}, fmt.Errorf("azure read job failed")
+		default: // "running", "notStarted"
+			select {
+			case <-ctx.Done():
+				return OCRResult{}, ctx.Err()
+			case <-time.After(pollInterval):
+			}
+		}
+	}
+	return OCRResult{}, fmt.Errorf("azure read poll: timed out after %d attempts", maxAttempts)
+}
+
+func toOCRResult(result azureReadResult) OCRResult {
+	var out OCRResult
+	var confidenceSum float32
+	var lineCount float32
+	for _, page := range result.AnalyzeResult.ReadResults {
+		for _, line := range page.Lines {
+			out.Text += line.Text + " "
+			out.Lines = append(out.Lines, Line{Text: line.Text, Confidence: line.Confidence * 100})
+			confidenceSum += line.Confidence * 100
+			lineCount++
+		}
+	}
+	if lineCount > 0 {
+		out.Confidence = confidenceSum / lineCount
+	}
+	return out
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+)
+
+// watchRenewMargin controls how long before a Gmail watch's 7-day
+// expiration the daemon re-registers it. Gmail stops delivering
+// notifications once a watch expires, so renewing early is cheap
+// insurance against clock drift or a slow restart.
+const watchRenewMargin = 24 * time.Hour
+
+// gmailPushNotification is the JSON payload Gmail publishes to the
+// Pub/Sub topic on each mailbox change.
+type gmailPushNotification struct {
+	EmailAddress string `json:"emailAddress"`
+	HistoryId    uint64 `json:"historyId"`
+}
+
+// runDaemon runs the tool continuously: it registers a Gmail watch, pulls
+// push notifications off the configured Pub/Sub subscription, and
+// processes only the messages added since the last notification. It blocks
+// until ctx is canceled or an unrecoverable error occurs.
+func runDaemon(ctx context.Context, p *pipeline, senderAddress string) error {
+	watcher, ok := p.provider.(HistoryWatcher)
+	if !ok {
+		return fmt.Errorf("daemon mode requires MAIL_PROVIDER=gmail (the configured provider doesn't support push notifications)")
+	}
+
+	topicName := os.Getenv("GMAIL_PUBSUB_TOPIC")
+	if topicName == "" {
+		return fmt.Errorf("GMAIL_PUBSUB_TOPIC is required in daemon mode")
+	}
+	projectID := os.Getenv("PUBSUB_PROJECT_ID")
+	if projectID == "" {
+		return fmt.Errorf("PUBSUB_PROJECT_ID is required in daemon mode")
+	}
+	subName := os.Getenv("PUBSUB_SUBSCRIPTION")
+	if subName == "" {
+		return fmt.Errorf("PUBSUB_SUBSCRIPTION is required in daemon mode")
+	}
+
+	historyId, expiration, err := watcher.Watch(ctx, topicName)
+	if err != nil {
+		return fmt.Errorf("initial gmail watch: %w", err)
+	}
+	log.Printf("Registered Gmail watch on %s, historyId=%d, expires %s", topicName, historyId, expiration)
+
+	d := &daemon{
+		pipeline:      p,
+		watcher:       watcher,
+		topicName:     topicName,
+		senderAddress: senderAddress,
+		lastHistory:   historyId,
+		watchExpires:  expiration,
+	}
+
+	go d.serveHealthz(getenvDefault("HEALTHZ_ADDR", ":8080"))
+	go d.renewWatchLoop(ctx)
+
+	client, err := pubsub.NewClient(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("pubsub client: %w", err)
+	}
+	defer client.Close()
+	sub := client.Subscription(subName)
+
+	log.Printf("Listening for Gmail push notifications on %s", subName)
+	return sub.Receive(ctx, func(ctx context.Context, msg *pubsub.Message) {
+		d.handleNotification(ctx, msg)
+	})
+}
+
+// daemon's lastHistory and watchExpires are read and written from three
+// separate goroutines: the pubsub Receive callback (which pubsub itself
+// invokes concurrently), renewWatchLoop, and the /healthz HTTP handler.
+// mu guards both fields against that concurrent access.
+type daemon struct {
+	pipeline      *pipeline
+	watcher       HistoryWatcher
+	topicName     string
+	senderAddress string
+
+	mu           sync.Mutex
+	lastHistory  uint64
+	watchExpires time.Time
+}
+
+func (d *daemon) handleNotification(ctx context.Context, msg *pubsub.Message) {
+	defer msg.Ack()
+
+	var notification gmailPushNotification
+	if err := json.Unmarshal(msg.Data, &notification); err != nil {
+		log.Printf("Error decoding push notification: %v", err)
+		return
+	}
+
+	d.mu.Lock()
+	lastHistory := d.lastHistory
+	d.mu.Unlock()
+
+	messageIds, newHistoryId, err := d.watcher.HistoryList(ctx, lastHistory)
+	if err != nil {
+		log.Printf("Error listing history since %d: %v", lastHistory, err)
+		return
+	}
+	d.mu.Lock()
+	d.lastHistory = newHistoryId
+	d.mu.Unlock()
+
+	log.Printf("Notification for %s: %d new message(s)", notification.EmailAddress, len(messageIds))
+	for _, id := range messageIds {
+		d.pipeline.processMessage(ctx, id, d.senderAddress)
+	}
+}
+
+// renewWatchLoop re-registers the Gmail watch shortly before it expires,
+// since Gmail does not renew watches automatically.
+func (d *daemon) renewWatchLoop(ctx context.Context) {
+	for {
+		d.mu.Lock()
+		sleepFor := time.Until(d.watchExpires) - watchRenewMargin
+		d.mu.Unlock()
+		if sleepFor < 0 {
+			sleepFor = 0
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(sleepFor):
+		}
+
+		historyId, expiration, err := d.watcher.Watch(ctx, d.topicName)
+		if err != nil {
+			log.Printf("Error renewing gmail watch: %v", err)
+			// Back off and retry rather than spinning if Gmail/Pub/Sub is
+			// briefly unavailable.
+			time.Sleep(time.Minute)
+			continue
+		}
+		d.mu.Lock()
+		d.watchExpires = expiration
+		if historyId > d.lastHistory {
+			d.lastHistory = historyId
+		}
+		d.mu.Unlock()
+		log.Printf("Renewed Gmail watch, expires %s", expiration)
+	}
+}
+
+func (d *daemon) serveHealthz(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		d.mu.Lock()
+		expired := time.Now().After(d.watchExpires)
+		d.mu.Unlock()
+		if expired {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	log.Printf("Serving /healthz on %s", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Printf("healthz server exited: %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+// levenshteinSimilarity returns the normalized edit-distance similarity of
+// a and b in [0, 1], where 1 means identical. It tolerates the kind of
+// single-character OCR noise (dropped/substituted letters, "l"/"1"/"I"
+// confusion) seen in mail-piece scans.
+func levenshteinSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	maxLen := len(a)
+	if len(b) > maxLen {
+		maxLen = len(b)
+	}
+	if maxLen == 0 {
+		return 1
+	}
+	dist := levenshteinDistance(a, b)
+	return 1 - float64(dist)/float64(maxLen)
+}
+
+func levenshteinDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			del := prev[j] + 1
+			ins := curr[j-1] + 1
+			sub := prev[j-1] + cost
+			curr[j] = min(del, ins, sub)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(br)]
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of a and b in [0, 1].
+// It weights matches at the start of the string more heavily, which suits
+// first/last names where OCR noise tends to land mid-word.
+func jaroWinkler(a, b string) float64 {
+	jaro := jaroSimilarity(a, b)
+	if jaro <= 0 {
+		return jaro
+	}
+	prefixLen := commonPrefixLen(a, b, 4)
+	const scalingFactor = 0.1
+	return jaro + float64(prefixLen)*scalingFactor*(1-jaro)
+}
+
+func jaroSimilarity(a, b string) float64 {
+	ar, br := []rune(a), []rune(b)
+	if len(ar) == 0 && len(br) == 0 {
+		return 1
+	}
+	if len(ar) == 0 || len(br) == 0 {
+		return 0
+	}
+
+	matchDistance := len(ar)/2 - 1
+	if len(br)/2-1 > matchDistance {
+		matchDistance = len(br)/2 - 1
+	}
+	if matchDistance < 0 {
+		matchDistance = 0
+	}
+
+	aMatched := make([]bool, len(ar))
+	bMatched := make([]bool, len(br))
+	matches := 0
+	for i := range ar {
+		lo := max(0, i-matchDistance)
+		hi := min(len(br), i+matchDistance+1)
+		for j := lo; j < hi; j++ {
+			if bMatched[j] || ar[i] != br[j] {
+				continue
+			}
+			aMatched[i] = true
+			bMatched[j] = true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	k := 0
+	for i := range ar {
+		if !aMatched[i] {
+			continue
+		}
+		for !bMatched[k] {
+			k++
+		}
+		if ar[i] != br[k] {
+			transpositions++
+		}
+		k++
+	}
+
+	m := float64(matches)
+	return (m/float64(len(ar)) + m/float64(len(br)) + (m-float64(transpositions)/2)/m) / 3
+}
+
+func commonPrefixLen(a, b string, limit int) int {
+	ar, br := []rune(a), []rune(b)
+	n := 0
+	for n < limit && n < len(ar) && n < len(br) && ar[n] == br[n] {
+		n++
+	}
+	return n
+}
+
+// metaphone returns a simplified phonetic code for s, used for
+// surname matching where spelling varies but pronunciation doesn't
+// (e.g. "Smyth" vs "Smith"). It is a reduced approximation of the
+// Metaphone algorithm: drop vowels after the first letter and collapse a
+// handful of common homophonic consonant pairs.
+func metaphone(s string) string {
+	if s == "" {
+		return ""
+	}
+	runes := []rune(s)
+	code := make([]rune, 0, len(runes))
+	code = append(code, normalizeMetaphoneRune(runes[0]))
+	for _, r := range runes[1:] {
+		n := normalizeMetaphoneRune(r)
+		switch n {
+		case 'a', 'e', 'i', 'o', 'u':
+			continue
+		}
+		code = append(code, n)
+	}
+	return string(code)
+}
+
+func normalizeMetaphoneRune(r rune) rune {
+	switch r {
+	case 'c', 'k', 'q':
+		return 'k'
+	case 'z', 's':
+		return 's'
+	case 'v':
+		return 'f'
+	case 'j', 'g':
+		return 'j'
+	case 'y', 'i':
+		return 'i'
+	default:
+		return r
+	}
+}
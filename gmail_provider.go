@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// gmailProvider implements MailProvider on top of the Gmail API. It is the
+// original (and default) backend; authenticateGmail, listMessages,
+// extractImages, modifyMessage, and trashMessage retain their historical
+// signatures so they can still be exercised independently of the
+// MailProvider abstraction.
+//
+// labelId is reached concurrently in daemon mode: pubsub's Receive callback
+// (and therefore pipeline.processMessage -> Label -> labelId) can run for up
+// to MaxOutstandingMessages notifications at once, so labelsMu guards the
+// lazily-populated labels cache against concurrent map access.
+type gmailProvider struct {
+	service  *gmail.Service
+	userId   string
+	labelsMu sync.Mutex
+	labels   map[string]string // labelName -> labelId, lazily populated
+}
+
+func newGmailProvider(ctx context.Context) (*gmailProvider, error) {
+	service := authenticateGmail(ctx)
+	return &gmailProvider{
+		service: service,
+		userId:  "me",
+		labels:  make(map[string]string),
+	}, nil
+}
+
+func (p *gmailProvider) Search(ctx context.Context, q MailQuery) ([]string, error) {
+	query := fmt.Sprintf("from:%s after:%s", q.From, q.Since.Format("2006/01/02"))
+	if q.ExcludeLabel != "" {
+		query = fmt.Sprintf("%s -label:%s", query, q.ExcludeLabel)
+	}
+	messages, err := listMessages(p.service, p.userId, query)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(messages))
+	for i, m := range messages {
+		ids[i] = m.Id
+	}
+	return ids, nil
+}
+
+func (p *gmailProvider) Fetch(ctx context.Context, id string) (*MailMessage, error) {
+	msg, err := p.service.Users.Messages.Get(p.userId, id).Do()
+	if err != nil {
+		return nil, err
+	}
+	return &MailMessage{
+		ID:      id,
+		Subject: getSubject(msg),
+		Sender:  getHeader(msg, "From"),
+		Images:  extractImages(p.service, msg),
+	}, nil
+}
+
+func (p *gmailProvider) Label(ctx context.Context, id, labelName string) error {
+	labelId, err := p.labelId(labelName)
+	if err != nil {
+		return err
+	}
+	return modifyMessage(p.service, p.userId, id, []string{labelId}, []string{"INBOX"})
+}
+
+func (p *gmailProvider) Trash(ctx context.Context, id string) error {
+	return trashMessage(p.service, p.userId, id)
+}
+
+func (p *gmailProvider) Close() error {
+	return nil
+}
+
+func (p *gmailProvider) labelId(labelName string) (string, error) {
+	p.labelsMu.Lock()
+	defer p.labelsMu.Unlock()
+
+	if id, ok := p.labels[labelName]; ok {
+		return id, nil
+	}
+	id, err := findOrCreateLabel(p.service, p.userId, labelName)
+	if err != nil {
+		return "", err
+	}
+	p.labels[labelName] = id
+	log.Printf("Resolved label %q to id %q", labelName, id)
+	return id, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// HistoryWatcher is implemented by mail providers that support Gmail-style
+// push notifications: a Users.watch subscription plus incremental
+// Users.History.List polling keyed by historyId. Only gmailProvider
+// implements it today; the IMAP backend has no equivalent and daemon mode
+// refuses to start against it.
+type HistoryWatcher interface {
+	// Watch (re-)registers a push subscription against topicName and
+	// returns the mailbox's current historyId and the subscription's
+	// expiration time.
+	Watch(ctx context.Context, topicName string) (historyId uint64, expiration time.Time, err error)
+
+	// HistoryList returns the IDs of messages added since startHistoryId,
+	// along with the historyId to resume from on the next call.
+	HistoryList(ctx context.Context, startHistoryId uint64) (messageIds []string, newHistoryId uint64, err error)
+}
+
+func (p *gmailProvider) Watch(ctx context.Context, topicName string) (uint64, time.Time, error) {
+	resp, err := p.service.Users.Watch(p.userId, &gmail.WatchRequest{
+		TopicName: topicName,
+		LabelIds:  []string{"INBOX"},
+	}).Do()
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("gmail watch: %w", err)
+	}
+	expiration := time.UnixMilli(resp.Expiration)
+	return resp.HistoryId, expiration, nil
+}
+
+func (p *gmailProvider) HistoryList(ctx context.Context, startHistoryId uint64) ([]string, uint64, error) {
+	var messageIds []string
+	newHistoryId := startHistoryId
+	pageToken := ""
+
+	for {
+		req := p.service.Users.History.List(p.userId).
+			StartHistoryId(startHistoryId).
+			HistoryTypes("messageAdded")
+		if pageToken != "" {
+			req = req.PageToken(pageToken)
+		}
+		res, err := req.Do()
+		if err != nil {
+			return nil, startHistoryId, fmt.Errorf("gmail history.list: %w", err)
+		}
+		for _, h := range res.History {
+			for _, added := range h.MessagesAdded {
+				messageIds = append(messageIds, added.Message.Id)
+			}
+		}
+		if res.HistoryId > newHistoryId {
+			newHistoryId = res.HistoryId
+		}
+		if res.NextPageToken == "" {
+			break
+		}
+		pageToken = res.NextPageToken
+	}
+	return messageIds, newHistoryId, nil
+}
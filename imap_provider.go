@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/emersion/go-imap"
+	move "github.com/emersion/go-imap-move"
+	"github.com/emersion/go-imap/client"
+)
+
+// imapProvider implements MailProvider over a single IMAP connection. It is
+// the non-Gmail backend, intended for Fastmail, Proton Bridge, iCloud, and
+// corporate Exchange/IMAP mailboxes. Configuration is read entirely from
+// env vars so it can be dropped in alongside the Gmail backend without code
+// changes; see newIMAPProvider for the full list.
+type imapProvider struct {
+	conn      *client.Client
+	mover     *move.Client // nil if the server doesn't advertise the MOVE extension
+	mailbox   string
+	trashName string
+	useMove   bool
+}
+
+func newIMAPProvider() (*imapProvider, error) {
+	host := os.Getenv("IMAP_HOST")
+	if host == "" {
+		return nil, fmt.Errorf("IMAP_HOST is required when MAIL_PROVIDER=imap")
+	}
+	port := getenvDefault("IMAP_PORT", "993")
+	user := os.Getenv("IMAP_USER")
+	pass := os.Getenv("IMAP_PASSWORD")
+	if user == "" || pass == "" {
+		return nil, fmt.Errorf("IMAP_USER and IMAP_PASSWORD are required when MAIL_PROVIDER=imap")
+	}
+	mailbox := getenvDefault("IMAP_MAILBOX", "INBOX")
+	trashName := getenvDefault("IMAP_TRASH_FOLDER", "Trash")
+
+	conn, err := client.DialTLS(fmt.Sprintf("%s:%s", host, port), nil)
+	if err != nil {
+		return nil, fmt.Errorf("imap dial: %w", err)
+	}
+	if err := conn.Login(user, pass); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("imap login: %w", err)
+	}
+	if _, err := conn.Select(mailbox, false); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("imap select %q: %w", mailbox, err)
+	}
+
+	p := &imapProvider{conn: conn, mailbox: mailbox, trashName: trashName}
+	if supported, err := conn.Support("MOVE"); err == nil && supported {
+		p.mover = move.NewClient(conn)
+		p.useMove = true
+	}
+	return p, nil
+}
+
+// Search returns message UIDs, not sequence numbers: sequence numbers are
+// only valid for the lifetime of the current connection and shift on every
+// EXPUNGE, which would silently retarget Fetch/Label/Trash at the wrong
+// message once any earlier message in the same batch is expunged.
+func (p *imapProvider) Search(ctx context.Context, q MailQuery) ([]string, error) {
+	criteria := imap.NewSearchCriteria()
+	criteria.Header.Add("From", q.From)
+	if !q.Since.IsZero() {
+		criteria.Since = q.Since
+	}
+	uids, err := p.conn.UidSearch(criteria)
+	if err != nil {
+		return nil, fmt.Errorf("imap search: %w", err)
+	}
+	ids := make([]string, len(uids))
+	for i, uid := range uids {
+		ids[i] = strconv.FormatUint(uint64(uid), 10)
+	}
+	return ids, nil
+}
+
+func (p *imapProvider) Fetch(ctx context.Context, id string) (*MailMessage, error) {
+	uidset, err := seqSetFor(id)
+	if err != nil {
+		return nil, err
+	}
+
+	section := &imap.BodySectionName{Peek: true}
+	items := []imap.FetchItem{imap.FetchEnvelope, section.FetchItem()}
+
+	messages := make(chan *imap.Message, 1)
+	done := make(chan error, 1)
+	go func() { done <- p.conn.UidFetch(uidset, items, messages) }()
+
+	var raw io.Reader
+	var subject, sender string
+	for msg := range messages {
+		if msg.Envelope != nil {
+			subject = msg.Envelope.Subject
+			if len(msg.Envelope.From) > 0 {
+				sender = msg.Envelope.From[0].Address()
+			}
+		}
+		if body := msg.GetBody(section); body != nil {
+			raw = body
+		}
+	}
+	if err := <-done; err != nil {
+		return nil, fmt.Errorf("imap fetch %s: %w", id, err)
+	}
+	if raw == nil {
+		return nil, fmt.Errorf("imap fetch %s: no body returned", id)
+	}
+
+	images, err := extractImagesFromMIME(raw)
+	if err != nil {
+		return nil, fmt.Errorf("imap parse %s: %w", id, err)
+	}
+
+	return &MailMessage{ID: id, Subject: subject, Sender: sender, Images: images}, nil
+}
+
+func (p *imapProvider) Label(ctx context.Context, id, labelName string) error {
+	uidset, err := seqSetFor(id)
+	if err != nil {
+		return err
+	}
+	if err := p.ensureMailbox(labelName); err != nil {
+		return err
+	}
+	if p.useMove {
+		return p.mover.UidMove(uidset, labelName)
+	}
+	return p.uidCopyDeleteExpunge(uidset, labelName)
+}
+
+func (p *imapProvider) Trash(ctx context.Context, id string) error {
+	uidset, err := seqSetFor(id)
+	if err != nil {
+		return err
+	}
+	if err := p.ensureMailbox(p.trashName); err != nil {
+		return err
+	}
+	if p.useMove {
+		return p.mover.UidMove(uidset, p.trashName)
+	}
+	return p.uidCopyDeleteExpunge(uidset, p.trashName)
+}
+
+// ensureMailbox creates name if it doesn't already exist, so Label/Trash
+// succeed against a fresh account that has never had this folder before
+// (e.g. a Fastmail/Proton Bridge/iCloud/Exchange inbox with no "USPS"
+// folder yet). An "already exists" response from CREATE is expected and
+// ignored; any other error is surfaced so the caller doesn't silently skip
+// the message.
+func (p *imapProvider) ensureMailbox(name string) error {
+	err := p.conn.Create(name)
+	if err == nil || strings.Contains(strings.ToLower(err.Error()), "exist") {
+		return nil
+	}
+	return fmt.Errorf("imap create %q: %w", name, err)
+}
+
+func (p *imapProvider) Close() error {
+	return p.conn.Logout()
+}
+
+// uidCopyDeleteExpunge implements a mailbox move for servers that don't
+// advertise the MOVE extension: UID COPY to the destination, mark
+// \Deleted on the source by UID, then EXPUNGE. The COPY and STORE steps are
+// UID-keyed so they're unaffected by sequence-number renumbering; the final
+// EXPUNGE is a plain EXPUNGE, which removes every \Deleted message in the
+// selected mailbox, not only this one.
+func (p *imapProvider) uidCopyDeleteExpunge(uidset *imap.SeqSet, destMailbox string) error {
+	if err := p.conn.UidCopy(uidset, destMailbox); err != nil {
+		return fmt.Errorf("imap uid copy to %q: %w", destMailbox, err)
+	}
+	storeItem := imap.FormatFlagsOp(imap.AddFlags, true)
+	if err := p.conn.UidStore(uidset, storeItem, []interface{}{imap.DeletedFlag}, nil); err != nil {
+		return fmt.Errorf("imap uid store \\Deleted: %w", err)
+	}
+	if err := p.conn.Expunge(nil); err != nil {
+		return fmt.Errorf("imap expunge: %w", err)
+	}
+	return nil
+}
+
+// seqSetFor builds a single-element imap.SeqSet from id. Despite the type
+// name (imap.SeqSet is used for both sequence-number and UID sets in
+// go-imap), every caller in this file treats it as a UID set.
+func seqSetFor(id string) (*imap.SeqSet, error) {
+	n, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid imap uid %q: %w", id, err)
+	}
+	uidset := new(imap.SeqSet)
+	uidset.AddNum(uint32(n))
+	return uidset, nil
+}
+
+// extractImagesFromMIME walks a raw RFC 822 message and returns the decoded
+// bytes of each image/* part. Unlike extractImages' recursive walk over
+// Gmail's MessagePart tree, it examines only an image body or the direct
+// children of a top-level multipart body; images inside nested multipart
+// parts are not returned.
+func extractImagesFromMIME(r io.Reader) ([][]byte, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, err
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		if !strings.HasPrefix(msg.Header.Get("Content-Type"), "image/") {
+			return nil, nil
+		}
+		mediaType = msg.Header.Get("Content-Type")
+	}
+
+	var images [][]byte
+	if strings.HasPrefix(mediaType, "image/") {
+		body := msg.Body
+		if strings.EqualFold(strings.TrimSpace(msg.Header.Get("Content-Transfer-Encoding")), "base64") {
+			body = base64.NewDecoder(base64.StdEncoding, body)
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		return append(images, data), nil
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, nil
+	}
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		partType := part.Header.Get("Content-Type")
+		if !strings.HasPrefix(partType, "image/") {
+			continue
+		}
+		var body io.Reader = part
+		if strings.EqualFold(strings.TrimSpace(part.Header.Get("Content-Transfer-Encoding")), "base64") {
+			body = base64.NewDecoder(base64.StdEncoding, part)
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			log.Printf("Error reading MIME part: %v", err)
+			continue
+		}
+		images = append(images, data)
+	}
+	return images, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// MailMessage is the provider-agnostic view of a single email that the
+// matching pipeline operates on.
+type MailMessage struct {
+	ID      string
+	Subject string
+	Sender  string
+	Images  [][]byte
+}
+
+// MailQuery describes the criteria used to find candidate USPS Informed
+// Delivery emails. Both the Gmail and IMAP backends can express this as a
+// native search (Gmail's `from:`/`after:` query string, IMAP's SEARCH
+// FROM/SINCE).
+type MailQuery struct {
+	From  string
+	Since time.Time
+
+	// ExcludeLabel, if set, asks the provider to skip messages already
+	// filed under this label/folder. Gmail supports this natively via
+	// `-label:`; the IMAP backend searches FROM/SINCE only and ignores it,
+	// since a message already moved out of the watched mailbox won't
+	// match the SELECTed folder anyway.
+	ExcludeLabel string
+}
+
+// MailProvider abstracts the mailbox operations the tool needs: finding
+// candidate messages, fetching their bodies/attachments, and filing the
+// result (label-and-archive or trash). The one-shot run calls a provider
+// sequentially from runOnce's loop; in daemon mode pubsub's Receive
+// callback can call it concurrently, so a provider accepted by daemon mode
+// (gmailProvider) guards its own shared state.
+type MailProvider interface {
+	// Search returns the IDs of messages matching q, newest work first is
+	// not guaranteed.
+	Search(ctx context.Context, q MailQuery) ([]string, error)
+
+	// Fetch retrieves the subject and any image attachments for id.
+	Fetch(ctx context.Context, id string) (*MailMessage, error)
+
+	// Label files the message under labelName (creating it if necessary)
+	// and removes it from the inbox.
+	Label(ctx context.Context, id, labelName string) error
+
+	// Trash moves the message to the provider's trash/deleted-items.
+	Trash(ctx context.Context, id string) error
+
+	// Close releases any resources (connections, etc.) held by the
+	// provider.
+	Close() error
+}
+
+// newMailProvider constructs the MailProvider selected by the
+// MAIL_PROVIDER env var ("gmail" or "imap"), defaulting to "gmail" to match
+// the tool's original behavior.
+func newMailProvider(ctx context.Context) (MailProvider, error) {
+	switch getenvDefault("MAIL_PROVIDER", "gmail") {
+	case "imap":
+		return newIMAPProvider()
+	case "gmail":
+		return newGmailProvider(ctx)
+	default:
+		return nil, fmt.Errorf("unknown MAIL_PROVIDER %q (want gmail or imap)", os.Getenv("MAIL_PROVIDER"))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,10 +12,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/textract"
-	"github.com/aws/aws-sdk-go-v2/service/textract/types"
-
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/gmail/v1"
@@ -22,85 +19,84 @@ import (
 )
 
 func main() {
-	ctx := context.Background()
-	gmailService := authenticateGmail(ctx)
-	cfg, err := config.LoadDefaultConfig(ctx)
-	if err != nil {
-		log.Fatalf("aws config: %v", err)
-	}
-	textractClient := textract.NewFromConfig(cfg)
+	daemonFlag := flag.Bool("daemon", false, "run continuously, processing mail as Gmail push notifications arrive")
+	replayFlag := flag.Bool("replay", false, "re-score stored OCR text against the current match config and exit, without calling the mailbox or OCR backend")
+	dryRunFlag := flag.Bool("dry-run", false, "record results to the store but never label or trash messages")
+	flag.Parse()
 
+	ctx := context.Background()
 	targetNames := loadTargetNamesFromEnv()
 	if len(targetNames) == 0 {
 		log.Fatalf("No target names configured. Set TARGET_NAMES or TARGET_NAMES_JSON.")
 	}
-	denyNames := loadDenyNamesFromEnv()
 
-	senderAddress := getenvDefault("SENDER_ADDRESS", "USPSInformeddelivery@email.informeddelivery.usps.com")
-	labelName := getenvDefault("USPS_LABEL", "USPS")
-	twoDaysAgo := time.Now().AddDate(0, 0, -2).Format("2006/01/02")
+	store, err := newSQLiteStore(getenvDefault("STATE_DB_PATH", "usps_checker.db"))
+	if err != nil {
+		log.Fatalf("Unable to open state store: %v", err)
+	}
+	defer func() {
+		if cerr := store.Close(); cerr != nil {
+			log.Printf("warning: closing state store: %v", cerr)
+		}
+	}()
+
+	if *replayFlag {
+		if err := runReplay(ctx, store, targetNames, loadMatchConfigFromEnv()); err != nil {
+			log.Fatalf("Replay failed: %v", err)
+		}
+		return
+	}
 
-	uspsLabelId, err := findOrCreateLabel(gmailService, "me", labelName)
+	provider, err := newMailProvider(ctx)
 	if err != nil {
-		log.Fatalf("Error finding or creating %s label: %v", labelName, err)
+		log.Fatalf("Unable to initialize mail provider: %v", err)
 	}
+	defer func() {
+		if cerr := provider.Close(); cerr != nil {
+			log.Printf("warning: closing mail provider: %v", cerr)
+		}
+	}()
 
-	query := fmt.Sprintf("from:%s after:%s -label:%s", senderAddress, twoDaysAgo, labelName)
-	log.Printf("Searching for emails with query: %s", query)
-	messages, err := listMessages(gmailService, "me", query)
+	ocrEngine, err := newOCREngine(ctx)
 	if err != nil {
-		log.Fatalf("Unable to retrieve messages: %v", err)
+		log.Fatalf("Unable to initialize OCR engine: %v", err)
 	}
-	log.Printf("Found %d messages", len(messages))
 
-	for _, message := range messages {
-		msg, err := gmailService.Users.Messages.Get("me", message.Id).Do()
-		if err != nil {
-			log.Printf("Error retrieving message %s: %v", message.Id, err)
-			continue
-		}
-		log.Printf("Processing: %s", getSubject(msg))
+	notifier, err := loadNotifyDispatcherFromEnv()
+	if err != nil {
+		log.Fatalf("Unable to configure notify sinks: %v", err)
+	}
 
-		images := extractImages(gmailService, msg)
-		nameFound := false
-		foundName := ""
+	archiver, err := newArchiverFromEnv()
+	if err != nil {
+		log.Fatalf("Unable to configure archive: %v", err)
+	}
 
-		for i, imageData := range images {
-			text, err := detectTextWithTextract(ctx, textractClient, imageData)
-			if err != nil {
-				log.Printf("Error detecting text: %v", err)
-				continue
-			}
-			lowerText := strings.ToLower(text)
-			if containsAny(lowerText, denyNames) {
-				log.Printf("Image %d contains deny term; skipping", i+1)
-				continue
-			}
-			for _, name := range targetNames {
-				if strings.Contains(lowerText, strings.ToLower(name)) {
-					log.Printf("Found '%s' in image %d!", name, i+1)
-					nameFound = true
-					foundName = name
-					break
-				}
-			}
-			if nameFound {
-				break
-			}
-		}
+	p := &pipeline{
+		provider:    provider,
+		ocrEngine:   ocrEngine,
+		targetNames: targetNames,
+		denyNames:   loadDenyNamesFromEnv(),
+		matchCfg:    loadMatchConfigFromEnv(),
+		labelName:   getenvDefault("USPS_LABEL", "USPS"),
+		store:       store,
+		dryRun:      *dryRunFlag,
+		notifier:    notifier,
+		archiver:    archiver,
+	}
+	senderAddress := getenvDefault("SENDER_ADDRESS", "USPSInformeddelivery@email.informeddelivery.usps.com")
 
-		if nameFound {
-			log.Printf("Adding %s label and removing from inbox (found name: %s)", labelName, foundName)
-			err = modifyMessage(gmailService, "me", message.Id, []string{uspsLabelId}, []string{"INBOX"})
-		} else {
-			log.Printf("Trashing email (no target names found)")
-			err = trashMessage(gmailService, "me", message.Id)
-		}
-		if err != nil {
-			log.Printf("Error processing: %v", err)
+	if *daemonFlag || getenvDefault("MODE", "") == "watch" {
+		if err := runDaemon(ctx, p, senderAddress); err != nil {
+			log.Fatalf("Daemon exited: %v", err)
 		}
+		return
+	}
+
+	twoDaysAgo := time.Now().AddDate(0, 0, -2)
+	if err := p.runOnce(ctx, senderAddress, twoDaysAgo); err != nil {
+		log.Fatalf("Unable to retrieve messages: %v", err)
 	}
-	log.Println("Complete")
 }
 
 // Helper functions
@@ -295,12 +291,19 @@ func listMessages(service *gmail.Service, userId string, query string) ([]*gmail
 }
 
 func getSubject(msg *gmail.Message) string {
+	if subject := getHeader(msg, "Subject"); subject != "" {
+		return subject
+	}
+	return "No Subject"
+}
+
+func getHeader(msg *gmail.Message, name string) string {
 	for _, header := range msg.Payload.Headers {
-		if header.Name == "Subject" {
+		if header.Name == name {
 			return header.Value
 		}
 	}
-	return "No Subject"
+	return ""
 }
 
 func extractImages(service *gmail.Service, msg *gmail.Message) [][]byte {
@@ -330,25 +333,6 @@ func extractImages(service *gmail.Service, msg *gmail.Message) [][]byte {
 	return images
 }
 
-func detectTextWithTextract(ctx context.Context, client *textract.Client, imageBytes []byte) (string, error) {
-	out, err := client.DetectDocumentText(ctx, &textract.DetectDocumentTextInput{
-		Document: &types.Document{Bytes: imageBytes},
-	})
-	if err != nil {
-		return "", err
-	}
-	var fullText strings.Builder
-	for _, block := range out.Blocks {
-		if block.BlockType == types.BlockTypeLine && block.Text != nil {
-			if _, err := fullText.WriteString(*block.Text + " "); err != nil {
-				// strings.Builder should not error, but handle to satisfy linters
-				return "", err
-			}
-		}
-	}
-	return fullText.String(), nil
-}
-
 func modifyMessage(service *gmail.Service, userId, messageId string, addLabels, removeLabels []string) error {
 	modification := &gmail.ModifyMessageRequest{AddLabelIds: addLabels, RemoveLabelIds: removeLabels}
 	_, err := service.Users.Messages.Modify(userId, messageId, modification).Do()
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MatchAlgo selects the scoring function used to compare an OCR token
+// against a target-name token.
+type MatchAlgo string
+
+const (
+	AlgoLevenshtein MatchAlgo = "levenshtein"
+	AlgoJaroWinkler MatchAlgo = "jarowinkler"
+	AlgoMetaphone   MatchAlgo = "metaphone"
+)
+
+// MatchConfig controls how loose the fuzzy name matcher is. It is loaded
+// once from the environment at startup.
+type MatchConfig struct {
+	Algo             MatchAlgo
+	MinScore         float64
+	RequireFirstLast bool
+	// MaxTokenGap bounds the distance, in token positions, between the
+	// first- and last-name matches on a line when RequireFirstLast is set
+	// (adjacent tokens are 1 apart, so 3 allows up to 2 tokens between).
+	MaxTokenGap int
+}
+
+// loadMatchConfigFromEnv reads MATCH_MIN_SCORE, MATCH_ALGO, and
+// MATCH_REQUIRE_FIRST_LAST, falling back to defaults tuned for
+// Informed Delivery scans (noisy but short mail-piece address blocks).
+func loadMatchConfigFromEnv() MatchConfig {
+	cfg := MatchConfig{
+		Algo:        AlgoJaroWinkler,
+		MinScore:    0.85,
+		MaxTokenGap: 3,
+	}
+	if algo := os.Getenv("MATCH_ALGO"); algo != "" {
+		cfg.Algo = MatchAlgo(algo)
+	}
+	if raw := os.Getenv("MATCH_MIN_SCORE"); raw != "" {
+		if v, err := strconv.ParseFloat(raw, 64); err == nil {
+			cfg.MinScore = v
+		} else {
+			log.Printf("warning: invalid MATCH_MIN_SCORE %q, using default %.2f", raw, cfg.MinScore)
+		}
+	}
+	if raw := os.Getenv("MATCH_REQUIRE_FIRST_LAST"); raw != "" {
+		cfg.RequireFirstLast = raw == "true"
+	}
+	return cfg
+}
+
+// MatchScore records how well a single target name scored against a line
+// of OCR text, for tuning via logs.
+type MatchScore struct {
+	TargetName string
+	Line       string
+	Score      float64
+}
+
+// findNameMatch scans OCR lines for the best-scoring target name under
+// cfg, returning whether a match cleared MinScore, which name matched, and
+// every score computed (for logging/tuning).
+func findNameMatch(lines []Line, targetNames []string, cfg MatchConfig) (bool, string, []MatchScore) {
+	var scores []MatchScore
+	bestName := ""
+	bestScore := 0.0
+
+	for _, line := range lines {
+		tokens := tokenize(line.Text)
+		for _, name := range targetNames {
+			nameTokens := tokenize(name)
+			var score float64
+			if cfg.RequireFirstLast && len(nameTokens) >= 2 {
+				score = scoreFirstLast(tokens, nameTokens, cfg)
+			} else {
+				score = scoreAnyOrder(tokens, nameTokens, cfg)
+			}
+			scores = append(scores, MatchScore{TargetName: name, Line: line.Text, Score: score})
+			if score > bestScore {
+				bestScore = score
+				bestName = name
+			}
+		}
+	}
+	return bestScore >= cfg.MinScore, bestName, scores
+}
+
+// scoreAnyOrder returns the average of each name token's best match
+// against any token in the line, regardless of position.
+func scoreAnyOrder(lineTokens, nameTokens []string, cfg MatchConfig) float64 {
+	if len(nameTokens) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, nameTok := range nameTokens {
+		sum += bestTokenScore(nameTok, lineTokens, cfg.Algo)
+	}
+	return sum / float64(len(nameTokens))
+}
+
+// scoreFirstLast requires the first and last tokens of the name to each
+// match some token in the line, within MaxTokenGap of each other, and
+// scores as the average of those two matches. Middle tokens (e.g. a
+// middle initial) are ignored.
+func scoreFirstLast(lineTokens, nameTokens []string, cfg MatchConfig) float64 {
+	first := nameTokens[0]
+	last := nameTokens[len(nameTokens)-1]
+
+	firstIdx, firstScore := bestTokenIndex(first, lineTokens, cfg.Algo)
+	lastIdx, lastScore := bestTokenIndex(last, lineTokens, cfg.Algo)
+	if firstIdx < 0 || lastIdx < 0 {
+		return 0
+	}
+	gap := lastIdx - firstIdx
+	if gap < 0 {
+		gap = -gap
+	}
+	if gap > cfg.MaxTokenGap {
+		return 0
+	}
+	return (firstScore + lastScore) / 2
+}
+
+func bestTokenScore(target string, candidates []string, algo MatchAlgo) float64 {
+	_, score := bestTokenIndex(target, candidates, algo)
+	return score
+}
+
+func bestTokenIndex(target string, candidates []string, algo MatchAlgo) (int, float64) {
+	bestIdx := -1
+	bestScore := 0.0
+	for i, candidate := range candidates {
+		score := tokenScore(target, candidate, algo)
+		if score > bestScore {
+			bestScore = score
+			bestIdx = i
+		}
+	}
+	return bestIdx, bestScore
+}
+
+func tokenScore(a, b string, algo MatchAlgo) float64 {
+	switch algo {
+	case AlgoLevenshtein:
+		return levenshteinSimilarity(a, b)
+	case AlgoMetaphone:
+		if metaphone(a) == metaphone(b) {
+			return 1
+		}
+		return 0
+	case AlgoJaroWinkler:
+		fallthrough
+	default:
+		return jaroWinkler(a, b)
+	}
+}
+
+func tokenize(s string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !('a' <= r && r <= 'z') && !('0' <= r && r <= '9')
+	})
+	return fields
+}
+
+// logMatchScores prints every score above a noise floor so thresholds can
+// be tuned from the logs without re-running Textract.
+func logMatchScores(scores []MatchScore) {
+	for _, s := range scores {
+		if s.Score <= 0 {
+			continue
+		}
+		log.Printf("match score %.2f: target=%q line=%q", s.Score, s.TargetName, s.Line)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// Notification carries everything a sink needs to tell a human that mail
+// matching one of their names just arrived.
+type Notification struct {
+	TargetName string
+	Sender     string
+	Subject    string
+	Image      []byte // the attachment that matched, inlined or attached per-sink
+}
+
+// Notifier is a single notification destination (Slack, Discord, ntfy,
+// Pushover, SMTP, ...).
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, n Notification) error
+}
+
+// NotifyDispatcher fans a Notification out to every configured sink,
+// optionally narrowed per target name via NOTIFY_ROUTES_JSON.
+type NotifyDispatcher struct {
+	sinks  map[string]Notifier
+	routes map[string][]string // targetName -> sink names; absent means "all sinks"
+}
+
+// loadNotifyDispatcherFromEnv builds a dispatcher from NOTIFY_SINKS (a
+// comma-separated list of slack, discord, ntfy, pushover, smtp) and the
+// per-sink env vars each one requires. Returns a dispatcher with no sinks,
+// not an error, if NOTIFY_SINKS is unset, so notifications are a no-op by
+// default.
+func loadNotifyDispatcherFromEnv() (*NotifyDispatcher, error) {
+	d := &NotifyDispatcher{sinks: make(map[string]Notifier)}
+
+	raw := os.Getenv("NOTIFY_SINKS")
+	if raw == "" {
+		return d, nil
+	}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		sink, err := newNotifier(name)
+		if err != nil {
+			return nil, fmt.Errorf("configuring notify sink %q: %w", name, err)
+		}
+		d.sinks[name] = sink
+	}
+
+	if routesJSON := os.Getenv("NOTIFY_ROUTES_JSON"); routesJSON != "" {
+		if err := json.Unmarshal([]byte(routesJSON), &d.routes); err != nil {
+			return nil, fmt.Errorf("parsing NOTIFY_ROUTES_JSON: %w", err)
+		}
+	}
+	return d, nil
+}
+
+func newNotifier(name string) (Notifier, error) {
+	switch name {
+	case "slack":
+		return newSlackNotifier()
+	case "discord":
+		return newDiscordNotifier()
+	case "ntfy":
+		return newNtfyNotifier()
+	case "pushover":
+		return newPushoverNotifier()
+	case "smtp":
+		return newSMTPNotifier()
+	default:
+		return nil, fmt.Errorf("unknown notify sink %q (want slack, discord, ntfy, pushover, or smtp)", name)
+	}
+}
+
+// Dispatch sends n to every sink configured for n.TargetName (or every
+// configured sink, if no route is defined for that name), logging rather
+// than failing the caller on a per-sink error.
+func (d *NotifyDispatcher) Dispatch(ctx context.Context, n Notification) {
+	if d == nil || len(d.sinks) == 0 {
+		return
+	}
+	for _, sink := range d.sinksFor(n.TargetName) {
+		if err := sink.Notify(ctx, n); err != nil {
+			log.Printf("Error notifying via %s: %v", sink.Name(), err)
+		}
+	}
+}
+
+func (d *NotifyDispatcher) sinksFor(targetName string) []Notifier {
+	names, routed := d.routes[targetName]
+	if !routed {
+		sinks := make([]Notifier, 0, len(d.sinks))
+		for _, sink := range d.sinks {
+			sinks = append(sinks, sink)
+		}
+		return sinks
+	}
+	sinks := make([]Notifier, 0, len(names))
+	for _, name := range names {
+		if sink, ok := d.sinks[name]; ok {
+			sinks = append(sinks, sink)
+		}
+	}
+	return sinks
+}
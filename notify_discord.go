@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"os"
+)
+
+// discordNotifier posts to a Discord webhook, attaching the matching
+// image as a multipart file alongside the payload_json content, per
+// Discord's webhook execute API.
+type discordNotifier struct {
+	webhookURL string
+}
+
+func newDiscordNotifier() (*discordNotifier, error) {
+	url := os.Getenv("DISCORD_WEBHOOK_URL")
+	if url == "" {
+		return nil, fmt.Errorf("DISCORD_WEBHOOK_URL is required for the discord sink")
+	}
+	return &discordNotifier{webhookURL: url}, nil
+}
+
+func (d *discordNotifier) Name() string { return "discord" }
+
+func (d *discordNotifier) Notify(ctx context.Context, n Notification) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	payload, err := json.Marshal(map[string]string{
+		"content": fmt.Sprintf("USPS Informed Delivery mail for **%s**\nFrom: %s\nSubject: %s", n.TargetName, n.Sender, n.Subject),
+	})
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteField("payload_json", string(payload)); err != nil {
+		return err
+	}
+	if len(n.Image) > 0 {
+		part, err := writer.CreateFormFile("files[0]", "match.jpg")
+		if err != nil {
+			return err
+		}
+		if _, err := part.Write(n.Image); err != nil {
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.webhookURL, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("discord webhook: unexpected status %s", resp.Status)
+	}
+	return nil
+}
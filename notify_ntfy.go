@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ntfyNotifier publishes to a ntfy.sh topic (or a self-hosted ntfy
+// server). When an image is present it's sent as the request body with
+// X-Filename set, which ntfy attaches to the notification; the subject
+// and sender go in headers so the notification stays readable without
+// opening the attachment.
+type ntfyNotifier struct {
+	baseURL string
+	topic   string
+	token   string
+}
+
+func newNtfyNotifier() (*ntfyNotifier, error) {
+	topic := os.Getenv("NTFY_TOPIC")
+	if topic == "" {
+		return nil, fmt.Errorf("NTFY_TOPIC is required for the ntfy sink")
+	}
+	return &ntfyNotifier{
+		baseURL: strings.TrimSuffix(getenvDefault("NTFY_URL", "https://ntfy.sh"), "/"),
+		topic:   topic,
+		token:   os.Getenv("NTFY_TOKEN"),
+	}, nil
+}
+
+func (nt *ntfyNotifier) Name() string { return "ntfy" }
+
+func (nt *ntfyNotifier) Notify(ctx context.Context, n Notification) error {
+	message := fmt.Sprintf("From: %s", n.Sender)
+	var body *bytes.Reader
+	if len(n.Image) > 0 {
+		body = bytes.NewReader(n.Image)
+	} else {
+		body = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, fmt.Sprintf("%s/%s", nt.baseURL, nt.topic), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Title", fmt.Sprintf("USPS mail for %s: %s", n.TargetName, n.Subject))
+	req.Header.Set("X-Message", message)
+	if len(n.Image) > 0 {
+		req.Header.Set("X-Filename", "match.jpg")
+	}
+	if nt.token != "" {
+		req.Header.Set("Authorization", "Bearer "+nt.token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ntfy publish: unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"os"
+)
+
+// pushoverNotifier posts to the Pushover Messages API, attaching the
+// matching image via the multipart "attachment" field.
+type pushoverNotifier struct {
+	token string
+	user  string
+}
+
+func newPushoverNotifier() (*pushoverNotifier, error) {
+	token := os.Getenv("PUSHOVER_TOKEN")
+	user := os.Getenv("PUSHOVER_USER")
+	if token == "" || user == "" {
+		return nil, fmt.Errorf("PUSHOVER_TOKEN and PUSHOVER_USER are required for the pushover sink")
+	}
+	return &pushoverNotifier{token: token, user: user}, nil
+}
+
+func (po *pushoverNotifier) Name() string { return "pushover" }
+
+func (po *pushoverNotifier) Notify(ctx context.Context, n Notification) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	fields := map[string]string{
+		"token":   po.token,
+		"user":    po.user,
+		"title":   fmt.Sprintf("USPS mail for %s", n.TargetName),
+		"message": fmt.Sprintf("From: %s\nSubject: %s", n.Sender, n.Subject),
+	}
+	for key, value := range fields {
+		if err := writer.WriteField(key, value); err != nil {
+			return err
+		}
+	}
+	if len(n.Image) > 0 {
+		part, err := writer.CreateFormFile("attachment", "match.jpg")
+		if err != nil {
+			return err
+		}
+		if _, err := part.Write(n.Image); err != nil {
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.pushover.net/1/messages.json", &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("pushover: unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// slackNotifier posts to an incoming webhook. Slack's legacy webhook API
+// doesn't accept file uploads, so the matching image is mentioned in text
+// rather than attached; routing to the Slack Files API would need a bot
+// token instead of a webhook URL, which is out of scope here.
+type slackNotifier struct {
+	webhookURL string
+}
+
+func newSlackNotifier() (*slackNotifier, error) {
+	url := os.Getenv("SLACK_WEBHOOK_URL")
+	if url == "" {
+		return nil, fmt.Errorf("SLACK_WEBHOOK_URL is required for the slack sink")
+	}
+	return &slackNotifier{webhookURL: url}, nil
+}
+
+func (s *slackNotifier) Name() string { return "slack" }
+
+func (s *slackNotifier) Notify(ctx context.Context, n Notification) error {
+	text := fmt.Sprintf("USPS Informed Delivery mail for *%s*\nFrom: %s\nSubject: %s", n.TargetName, n.Sender, n.Subject)
+	if len(n.Image) > 0 {
+		text += "\n(The matching mail-piece image is not attached: Slack webhooks don't accept files.)"
+	}
+	payload := map[string]string{
+		"text": text,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack webhook: unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime/multipart"
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+// smtpNotifier sends a plain MIME email with the matching image as a
+// file attachment, via a standard SMTP submission (auth + STARTTLS is handled by
+// net/smtp.SendMail against the usual 587 submission port).
+type smtpNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+}
+
+func newSMTPNotifier() (*smtpNotifier, error) {
+	host := os.Getenv("SMTP_HOST")
+	from := os.Getenv("SMTP_FROM")
+	toRaw := os.Getenv("SMTP_TO")
+	if host == "" || from == "" || toRaw == "" {
+		return nil, fmt.Errorf("SMTP_HOST, SMTP_FROM, and SMTP_TO are required for the smtp sink")
+	}
+	port := getenvDefault("SMTP_PORT", "587")
+
+	var auth smtp.Auth
+	if user := os.Getenv("SMTP_USER"); user != "" {
+		auth = smtp.PlainAuth("", user, os.Getenv("SMTP_PASSWORD"), host)
+	}
+
+	to := strings.Split(toRaw, ",")
+	for i := range to {
+		to[i] = strings.TrimSpace(to[i])
+	}
+
+	return &smtpNotifier{addr: fmt.Sprintf("%s:%s", host, port), auth: auth, from: from, to: to}, nil
+}
+
+func (s *smtpNotifier) Name() string { return "smtp" }
+
+func (s *smtpNotifier) Notify(ctx context.Context, n Notification) error {
+	msg, err := s.buildMessage(n)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(s.addr, s.auth, s.from, s.to, msg)
+}
+
+func (s *smtpNotifier) buildMessage(n Notification) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", s.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(s.to, ", "))
+	fmt.Fprintf(&buf, "Subject: USPS Informed Delivery mail for %s\r\n", n.TargetName)
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", writer.Boundary())
+
+	textPart, err := writer.CreatePart(map[string][]string{
+		"Content-Type": {"text/plain; charset=UTF-8"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(textPart, "From: %s\nSubject: %s\n", n.Sender, n.Subject)
+
+	if len(n.Image) > 0 {
+		imgPart, err := writer.CreatePart(map[string][]string{
+			"Content-Type":              {"image/jpeg"},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {`attachment; filename="match.jpg"`},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoded := base64.StdEncoding.EncodeToString(n.Image)
+		if _, err := imgPart.Write([]byte(encoded)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
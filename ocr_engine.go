@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// Line is a single recognized line of text with its confidence, as
+// reported by the OCR backend.
+type Line struct {
+	Text       string
+	Confidence float32
+}
+
+// OCRResult is the normalized output of any OCREngine, so the
+// name-matching code downstream never needs to know which backend produced
+// it.
+type OCRResult struct {
+	Text       string
+	Lines      []Line
+	Confidence float32 // overall/document-level confidence, 0-100
+}
+
+// OCREngine abstracts text detection over an image so backends can be
+// swapped without touching the matching pipeline.
+type OCREngine interface {
+	DetectText(ctx context.Context, imageBytes []byte) (OCRResult, error)
+}
+
+// newOCREngine constructs the OCREngine selected by the OCR_BACKEND env
+// var ("textract", "gcv", "azure", or "tesseract"), defaulting to
+// "textract" to match the tool's original behavior.
+func newOCREngine(ctx context.Context) (OCREngine, error) {
+	switch getenvDefault("OCR_BACKEND", "textract") {
+	case "textract":
+		return newTextractEngine(ctx)
+	case "gcv":
+		return newGCVEngine(ctx)
+	case "azure":
+		return newAzureEngine()
+	case "tesseract":
+		return newTesseractEngine()
+	default:
+		return nil, fmt.Errorf("unknown OCR_BACKEND %q (want textract, gcv, azure, or tesseract)", getenvDefault("OCR_BACKEND", "textract"))
+	}
+}
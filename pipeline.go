@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+)
+
+// pipeline bundles the configured provider, OCR engine, and matching
+// rules so both the one-shot run in main() and the daemon's per-message
+// callback can share the exact same processing logic.
+type pipeline struct {
+	provider    MailProvider
+	ocrEngine   OCREngine
+	targetNames []string
+	denyNames   []string
+	matchCfg    MatchConfig
+	labelName   string
+	store       Store
+	dryRun      bool
+	notifier    *NotifyDispatcher
+	archiver    *Archiver
+}
+
+// runOnce searches for candidate messages and processes each one. This is
+// the tool's original cron/manual-invocation behavior.
+func (p *pipeline) runOnce(ctx context.Context, senderAddress string, since time.Time) error {
+	log.Printf("Searching for emails from %s since %s", senderAddress, since.Format("2006/01/02"))
+	messageIds, err := p.provider.Search(ctx, MailQuery{From: senderAddress, Since: since, ExcludeLabel: p.labelName})
+	if err != nil {
+		return err
+	}
+	log.Printf("Found %d messages", len(messageIds))
+
+	for _, id := range messageIds {
+		p.processMessage(ctx, id, senderAddress)
+	}
+	log.Println("Complete")
+	return nil
+}
+
+// processMessage fetches, OCRs, matches, and files a single message. Errors
+// are logged rather than returned so a daemon processing a batch of
+// history events doesn't abort the whole batch on one bad message.
+//
+// If p.store already has a record for id, processing is skipped entirely
+// so reruns are idempotent regardless of whether the mailbox-side query
+// filter (e.g. Gmail's -label:USPS) caught it.
+//
+// senderAddress, if non-empty, restricts processing to messages from that
+// sender; anything else is left untouched in the inbox. runOnce's search
+// query already narrows by sender server-side, but the daemon's history
+// watch has no such filter (it watches the whole inbox), so without this
+// check handleNotification would trash the user's ordinary mail.
+func (p *pipeline) processMessage(ctx context.Context, id, senderAddress string) {
+	if p.store != nil {
+		if processed, err := p.store.IsProcessed(ctx, id); err != nil {
+			log.Printf("Error checking processed state for %s: %v", id, err)
+		} else if processed {
+			log.Printf("Skipping %s: already processed", id)
+			return
+		}
+	}
+
+	msg, err := p.provider.Fetch(ctx, id)
+	if err != nil {
+		log.Printf("Error retrieving message %s: %v", id, err)
+		return
+	}
+
+	if senderAddress != "" && !strings.Contains(strings.ToLower(msg.Sender), strings.ToLower(senderAddress)) {
+		log.Printf("Skipping %s: sender %q doesn't match %q", id, msg.Sender, senderAddress)
+		return
+	}
+
+	log.Printf("Processing: %s", msg.Subject)
+
+	nameFound := false
+	foundName := ""
+	var matchedImage []byte
+	var storedImages []StoredImageOCR
+
+	for i, imageData := range msg.Images {
+		ocrResult, err := p.ocrEngine.DetectText(ctx, imageData)
+		if err != nil {
+			log.Printf("Error detecting text: %v", err)
+			continue
+		}
+		storedImages = append(storedImages, StoredImageOCR{Index: i, Lines: ocrResult.Lines})
+
+		lowerText := strings.ToLower(ocrResult.Text)
+		if containsAny(lowerText, p.denyNames) {
+			log.Printf("Image %d contains deny term; skipping", i+1)
+			continue
+		}
+		matched, name, scores := findNameMatch(ocrResult.Lines, p.targetNames, p.matchCfg)
+		logMatchScores(scores)
+		if matched {
+			log.Printf("Found '%s' in image %d!", name, i+1)
+			nameFound = true
+			foundName = name
+			matchedImage = imageData
+		}
+		if nameFound {
+			break
+		}
+	}
+
+	if nameFound {
+		p.notifier.Dispatch(ctx, Notification{
+			TargetName: foundName,
+			Sender:     msg.Sender,
+			Subject:    msg.Subject,
+			Image:      matchedImage,
+		})
+	}
+
+	archiveLabel := "TRASH"
+	if nameFound {
+		archiveLabel = p.labelName
+	}
+	if err := p.archiver.Archive(msg, joinOCRText(storedImages), foundName, []string{archiveLabel}); err != nil {
+		log.Printf("Error archiving %s: %v", id, err)
+	}
+
+	action := p.fileMessage(ctx, id, nameFound, foundName)
+
+	if p.store != nil {
+		record := ProcessedMessage{
+			ID:          id,
+			Subject:     msg.Subject,
+			Images:      storedImages,
+			MatchedName: foundName,
+			Action:      action,
+			ProcessedAt: time.Now(),
+		}
+		if err := p.store.Record(ctx, record); err != nil {
+			log.Printf("Error recording %s to store: %v", id, err)
+		}
+	}
+}
+
+// joinOCRText concatenates every recognized line across a message's
+// images, in image/line order, for the archive's X-USPS-OCR-Text header and
+// text part.
+func joinOCRText(images []StoredImageOCR) string {
+	var b strings.Builder
+	for _, img := range images {
+		for _, line := range img.Lines {
+			b.WriteString(line.Text)
+			b.WriteString(" ")
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// fileMessage labels or trashes the message per nameFound, skipping the
+// mailbox mutation entirely in dry-run mode, and returns the action label
+// recorded to the store.
+func (p *pipeline) fileMessage(ctx context.Context, id string, nameFound bool, foundName string) string {
+	if p.dryRun {
+		if nameFound {
+			log.Printf("[dry-run] Would add %s label and remove from inbox (found name: %s)", p.labelName, foundName)
+			return "dry-run-label"
+		}
+		log.Printf("[dry-run] Would trash email (no target names found)")
+		return "dry-run-trash"
+	}
+
+	var err error
+	var action string
+	if nameFound {
+		log.Printf("Adding %s label and removing from inbox (found name: %s)", p.labelName, foundName)
+		err = p.provider.Label(ctx, id, p.labelName)
+		action = "labeled"
+		if err != nil {
+			action = "label-failed"
+		}
+	} else {
+		log.Printf("Trashing email (no target names found)")
+		err = p.provider.Trash(ctx, id)
+		action = "trashed"
+		if err != nil {
+			action = "trash-failed"
+		}
+	}
+	if err != nil {
+		log.Printf("Error processing %s: %v", id, err)
+	}
+	return action
+}
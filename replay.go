@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"log"
+)
+
+// runReplay re-scores every stored message's OCR text against the current
+// target names and match config, without calling the OCR backend or
+// touching the mailbox. It's meant for tuning MATCH_* settings against
+// mail already processed: run once to build up the store, tweak
+// MATCH_MIN_SCORE/MATCH_ALGO, then --replay to see how the new settings
+// would have scored.
+func runReplay(ctx context.Context, store Store, targetNames []string, matchCfg MatchConfig) error {
+	messages, err := store.All(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("Replaying %d stored messages", len(messages))
+
+	for _, msg := range messages {
+		nameFound := false
+		foundName := ""
+		for _, img := range msg.Images {
+			matched, name, scores := findNameMatch(img.Lines, targetNames, matchCfg)
+			logMatchScores(scores)
+			if matched {
+				nameFound = true
+				foundName = name
+				break
+			}
+		}
+		if nameFound {
+			log.Printf("[replay] %s (%s): would match %q (previously: %s)", msg.ID, msg.Subject, foundName, msg.Action)
+		} else {
+			log.Printf("[replay] %s (%s): no match (previously: %s)", msg.ID, msg.Subject, msg.Action)
+		}
+	}
+	return nil
+}
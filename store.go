@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// ProcessedMessage is one row of the processed-message audit trail: what
+// was seen, what OCR found, what matched, and what the tool did about it.
+type ProcessedMessage struct {
+	ID          string
+	Subject     string
+	Images      []StoredImageOCR
+	MatchedName string
+	Action      string // "labeled", "trashed", "label-failed", "trash-failed", "dry-run-label", or "dry-run-trash"
+	ProcessedAt time.Time
+}
+
+// StoredImageOCR is the OCR output for a single image attachment, kept so
+// --replay can re-run matching without re-billing the OCR backend.
+type StoredImageOCR struct {
+	Index int
+	Lines []Line
+}
+
+// Store persists ProcessedMessage rows so reruns can skip messages already
+// handled and --replay can re-score stored OCR text against new matching
+// rules.
+type Store interface {
+	// IsProcessed reports whether id has already been recorded, so the
+	// caller can skip re-fetching and re-OCRing it.
+	IsProcessed(ctx context.Context, id string) (bool, error)
+
+	// Record upserts the result of processing a message.
+	Record(ctx context.Context, msg ProcessedMessage) error
+
+	// All returns every recorded message, oldest first, for --replay.
+	All(ctx context.Context) ([]ProcessedMessage, error)
+
+	Close() error
+}
+
+// sqliteStore implements Store on top of modernc.org/sqlite, a pure-Go
+// driver so the tool keeps its single-binary, no-cgo build.
+type sqliteStore struct {
+	db *sql.DB
+}
+
+func newSQLiteStore(path string) (*sqliteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite store %q: %w", path, err)
+	}
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS messages (
+			id            TEXT PRIMARY KEY,
+			subject       TEXT NOT NULL,
+			matched_name  TEXT NOT NULL DEFAULT '',
+			action        TEXT NOT NULL,
+			processed_at  DATETIME NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS image_ocr (
+			message_id  TEXT NOT NULL REFERENCES messages(id),
+			image_index INTEGER NOT NULL,
+			lines_json  TEXT NOT NULL,
+			PRIMARY KEY (message_id, image_index)
+		);
+	`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate sqlite store: %w", err)
+	}
+	return &sqliteStore{db: db}, nil
+}
+
+func (s *sqliteStore) IsProcessed(ctx context.Context, id string) (bool, error) {
+	var exists int
+	err := s.db.QueryRowContext(ctx, `SELECT 1 FROM messages WHERE id = ?`, id).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *sqliteStore) Record(ctx context.Context, msg ProcessedMessage) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO messages (id, subject, matched_name, action, processed_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			subject = excluded.subject,
+			matched_name = excluded.matched_name,
+			action = excluded.action,
+			processed_at = excluded.processed_at
+	`, msg.ID, msg.Subject, msg.MatchedName, msg.Action, msg.ProcessedAt); err != nil {
+		return fmt.Errorf("insert message: %w", err)
+	}
+
+	for _, img := range msg.Images {
+		linesJSON, err := json.Marshal(img.Lines)
+		if err != nil {
+			return fmt.Errorf("marshal ocr lines: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO image_ocr (message_id, image_index, lines_json)
+			VALUES (?, ?, ?)
+			ON CONFLICT(message_id, image_index) DO UPDATE SET lines_json = excluded.lines_json
+		`, msg.ID, img.Index, string(linesJSON)); err != nil {
+			return fmt.Errorf("insert image ocr: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *sqliteStore) All(ctx context.Context) ([]ProcessedMessage, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, subject, matched_name, action, processed_at FROM messages ORDER BY processed_at ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []ProcessedMessage
+	for rows.Next() {
+		var msg ProcessedMessage
+		if err := rows.Scan(&msg.ID, &msg.Subject, &msg.MatchedName, &msg.Action, &msg.ProcessedAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range messages {
+		images, err := s.imagesFor(ctx, messages[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		messages[i].Images = images
+	}
+	return messages, nil
+}
+
+func (s *sqliteStore) imagesFor(ctx context.Context, messageId string) ([]StoredImageOCR, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT image_index, lines_json FROM image_ocr WHERE message_id = ? ORDER BY image_index ASC
+	`, messageId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var images []StoredImageOCR
+	for rows.Next() {
+		var img StoredImageOCR
+		var linesJSON string
+		if err := rows.Scan(&img.Index, &linesJSON); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(linesJSON), &img.Lines); err != nil {
+			return nil, fmt.Errorf("unmarshal ocr lines for %s: %w", messageId, err)
+		}
+		images = append(images, img)
+	}
+	return images, rows.Err()
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// tesseractEngine implements OCREngine by shelling out to the `tesseract`
+// CLI. It has no cloud dependency or per-call cost, at the expense of
+// accuracy on noisy mail-piece scans and not reporting per-line confidence
+// (tesseract's TSV output reports per-word confidence, which we average).
+type tesseractEngine struct {
+	binPath string
+	lang    string
+}
+
+func newTesseractEngine() (*tesseractEngine, error) {
+	binPath := getenvDefault("TESSERACT_PATH", "tesseract")
+	if _, err := exec.LookPath(binPath); err != nil {
+		return nil, fmt.Errorf("tesseract binary %q not found in PATH: %w", binPath, err)
+	}
+	return &tesseractEngine{
+		binPath: binPath,
+		lang:    getenvDefault("TESSERACT_LANG", "eng"),
+	}, nil
+}
+
+func (e *tesseractEngine) DetectText(ctx context.Context, imageBytes []byte) (OCRResult, error) {
+	tmp, err := os.CreateTemp("", "usps-ocr-*.png")
+	if err != nil {
+		return OCRResult{}, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(imageBytes); err != nil {
+		tmp.Close()
+		return OCRResult{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return OCRResult{}, err
+	}
+
+	// "tsv" output gives per-word confidence; "stdout" with no extension
+	// gives plain text. We ask for TSV and reconstruct both views from it.
+	cmd := exec.CommandContext(ctx, e.binPath, tmp.Name(), "stdout", "-l", e.lang, "tsv")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return OCRResult{}, fmt.Errorf("tesseract: %w: %s", err, stderr.String())
+	}
+	return parseTesseractTSV(stdout.String()), nil
+}
+
+// parseTesseractTSV reconstructs OCRResult.Text and per-line confidence
+// from tesseract's TSV output, which has one row per detected word with
+// columns including block_num, par_num, line_num, conf, and text.
+// line_num resets to 0 at the start of every new block/paragraph, so a
+// line key must include block_num and par_num too — keying on line_num
+// alone would concatenate unrelated lines from different blocks that
+// happen to share a line_num.
+func parseTesseractTSV(tsv string) OCRResult {
+	type lineAccum struct {
+		text          strings.Builder
+		confidenceSum float32
+		wordCount     float32
+	}
+	lines := make(map[string]*lineAccum)
+	var order []string
+
+	rows := strings.Split(tsv, "\n")
+	for i, row := range rows {
+		if i == 0 || row == "" {
+			continue // header row
+		}
+		cols := strings.Split(row, "\t")
+		if len(cols) < 12 {
+			continue
+		}
+		lineKey := strings.Join(cols[2:5], "/") // block_num/par_num/line_num
+		conf64, _ := strconv.ParseFloat(cols[10], 32)
+		conf := float32(conf64)
+		text := cols[11]
+		if text == "" {
+			continue
+		}
+		acc, ok := lines[lineKey]
+		if !ok {
+			acc = &lineAccum{}
+			lines[lineKey] = acc
+			order = append(order, lineKey)
+		}
+		if acc.text.Len() > 0 {
+			acc.text.WriteString(" ")
+		}
+		acc.text.WriteString(text)
+		acc.confidenceSum += conf
+		acc.wordCount++
+	}
+
+	var result OCRResult
+	var confidenceSum float32
+	for _, lineKey := range order {
+		acc := lines[lineKey]
+		lineText := acc.text.String()
+		lineConfidence := float32(0)
+		if acc.wordCount > 0 {
+			lineConfidence = acc.confidenceSum / acc.wordCount
+		}
+		result.Text += lineText + " "
+		result.Lines = append(result.Lines, Line{Text: lineText, Confidence: lineConfidence})
+		confidenceSum += lineConfidence
+	}
+	if len(result.Lines) > 0 {
+		result.Confidence = confidenceSum / float32(len(result.Lines))
+	}
+	return result
+}
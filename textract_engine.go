@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/textract"
+	"github.com/aws/aws-sdk-go-v2/service/textract/types"
+)
+
+// textractEngine implements OCREngine via AWS Textract's synchronous
+// DetectDocumentText API. It is the original (and default) backend.
+type textractEngine struct {
+	client *textract.Client
+}
+
+func newTextractEngine(ctx context.Context) (*textractEngine, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &textractEngine{client: textract.NewFromConfig(cfg)}, nil
+}
+
+func (e *textractEngine) DetectText(ctx context.Context, imageBytes []byte) (OCRResult, error) {
+	out, err := e.client.DetectDocumentText(ctx, &textract.DetectDocumentTextInput{
+		Document: &types.Document{Bytes: imageBytes},
+	})
+	if err != nil {
+		return OCRResult{}, err
+	}
+
+	var result OCRResult
+	var lineCount float32
+	for _, block := range out.Blocks {
+		if block.BlockType != types.BlockTypeLine || block.Text == nil {
+			continue
+		}
+		confidence := float32(0)
+		if block.Confidence != nil {
+			confidence = *block.Confidence
+		}
+		result.Text += *block.Text + " "
+		result.Lines = append(result.Lines, Line{Text: *block.Text, Confidence: confidence})
+		result.Confidence += confidence
+		lineCount++
+	}
+	if lineCount > 0 {
+		result.Confidence /= lineCount
+	}
+	return result, nil
+}